@@ -0,0 +1,70 @@
+package grpc
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/brunotm/replicant/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authenticate extracts the `authorization` metadata value from ctx and
+// runs it through auth, reusing the same server.Authenticator used by
+// the REST API.
+func authenticate(ctx context.Context, auth server.Authenticator) (err error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return server.ErrUnauthorized
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return server.ErrUnauthorized
+	}
+
+	// URL must be non-nil: authenticators such as the internal backend
+	// read r.URL.Path to apply per-path ACLs.
+	req := &http.Request{URL: &url.URL{}, Header: http.Header{"Authorization": values[:1]}}
+	return auth.Authenticate(req)
+}
+
+// unaryAuthInterceptor rejects unary calls that fail auth.Authenticate.
+func unaryAuthInterceptor(auth server.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authenticate(ctx, auth); err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamAuthInterceptor rejects streaming calls that fail
+// auth.Authenticate.
+func streamAuthInterceptor(auth server.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authenticate(ss.Context(), auth); err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(srv, ss)
+	}
+}
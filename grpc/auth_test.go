@@ -0,0 +1,59 @@
+package grpc
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/brunotm/replicant/server"
+	"google.golang.org/grpc/metadata"
+)
+
+// pathReadingAuthenticator mimics an Authenticator, such as the server
+// package's internal backend, that dereferences r.URL to apply per-path
+// ACLs. Feeding it a request with a nil URL is the exact regression
+// authenticate must not reintroduce.
+type pathReadingAuthenticator struct{}
+
+func (pathReadingAuthenticator) Authenticate(r *http.Request) (err error) {
+	_ = r.URL.Path
+	return nil
+}
+
+func TestAuthenticateDoesNotPanicOnPathReadingAuthenticator(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer token"))
+
+	if err := authenticate(ctx, pathReadingAuthenticator{}); err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+}
+
+func TestAuthenticateMissingMetadata(t *testing.T) {
+	if err := authenticate(context.Background(), pathReadingAuthenticator{}); err != server.ErrUnauthorized {
+		t.Fatalf("authenticate() = %v, want %v", err, server.ErrUnauthorized)
+	}
+}
+
+func TestAuthenticateMissingAuthorizationHeader(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{})
+
+	if err := authenticate(ctx, pathReadingAuthenticator{}); err != server.ErrUnauthorized {
+		t.Fatalf("authenticate() = %v, want %v", err, server.ErrUnauthorized)
+	}
+}
@@ -0,0 +1,59 @@
+package grpc
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/encoding"
+)
+
+// legacyProtoCodec marshals with github.com/golang/protobuf, which only
+// requires a message to implement Reset/String/ProtoMessage (satisfied
+// by the hand-written types in replicant.pb.go) and bridges them into
+// google.golang.org/protobuf through reflection over their struct tags.
+//
+// grpc-go's built-in "proto" codec instead requires messages to
+// implement ProtoReflect() from protoc-gen-go's v2 output, which these
+// types do not have; registering this codec under the same name
+// overrides it so the Replicant service can actually marshal requests.
+type legacyProtoCodec struct{}
+
+func (legacyProtoCodec) Marshal(v interface{}) ([]byte, error) {
+	vv, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("grpc: failed to marshal, message is %T, want proto.Message", v)
+	}
+	return proto.Marshal(vv)
+}
+
+func (legacyProtoCodec) Unmarshal(data []byte, v interface{}) error {
+	vv, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("grpc: failed to unmarshal, message is %T, want proto.Message", v)
+	}
+	return proto.Unmarshal(data, vv)
+}
+
+func (legacyProtoCodec) Name() string {
+	return "proto"
+}
+
+func init() {
+	encoding.RegisterCodec(legacyProtoCodec{})
+}
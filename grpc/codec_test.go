@@ -0,0 +1,61 @@
+package grpc
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/encoding"
+)
+
+func TestLegacyProtoCodecRegisteredUnderProto(t *testing.T) {
+	codec := encoding.GetCodec("proto")
+	if _, ok := codec.(legacyProtoCodec); !ok {
+		t.Fatalf("codec registered under %q is %T, want legacyProtoCodec", "proto", codec)
+	}
+}
+
+func TestLegacyProtoCodecRoundTrip(t *testing.T) {
+	var codec legacyProtoCodec
+
+	in := &Transaction{Name: "foo", Config: []byte("bar")}
+	data, err := codec.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	out := &Transaction{}
+	if err = codec.Unmarshal(data, out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if out.Name != in.Name || string(out.Config) != string(in.Config) {
+		t.Fatalf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestLegacyProtoCodecRejectsNonProtoMessage(t *testing.T) {
+	var codec legacyProtoCodec
+
+	if _, err := codec.Marshal("not a proto.Message"); err == nil {
+		t.Fatal("Marshal succeeded on a non proto.Message value")
+	}
+
+	if err := codec.Unmarshal(nil, "not a proto.Message"); err == nil {
+		t.Fatal("Unmarshal succeeded on a non proto.Message value")
+	}
+}
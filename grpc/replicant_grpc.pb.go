@@ -0,0 +1,240 @@
+// This file implements the gRPC server and client plumbing for the
+// replicant.v1.Replicant service declared in replicant.proto. It is
+// hand-maintained, not protoc-gen-go-grpc output, to pair with the
+// hand-written message types in replicant.pb.go.
+
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// ReplicantServer is the server API for the Replicant service.
+type ReplicantServer interface {
+	CreateTransaction(context.Context, *Transaction) (*Transaction, error)
+	GetTransaction(context.Context, *GetTransactionRequest) (*Transaction, error)
+	DeleteTransaction(context.Context, *DeleteTransactionRequest) (*Empty, error)
+	ListTransactions(context.Context, *Empty) (*ListTransactionsResponse, error)
+	RunTransaction(*RunTransactionRequest, Replicant_RunTransactionServer) error
+	GetStatus(context.Context, *Empty) (*StatusResponse, error)
+}
+
+// Replicant_RunTransactionServer streams Result messages back to the
+// client for a single RunTransaction call.
+type Replicant_RunTransactionServer interface {
+	Send(*Result) error
+	grpc.ServerStream
+}
+
+type replicantRunTransactionServer struct {
+	grpc.ServerStream
+}
+
+func (s *replicantRunTransactionServer) Send(m *Result) error {
+	return s.ServerStream.SendMsg(m)
+}
+
+// RegisterReplicantServer registers srv as the implementation of the
+// replicant.v1.Replicant service on s.
+func RegisterReplicantServer(s *grpc.Server, srv ReplicantServer) {
+	s.RegisterService(&replicantServiceDesc, srv)
+}
+
+// ReplicantClient is the client API for the Replicant service.
+type ReplicantClient interface {
+	CreateTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Transaction, error)
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*Transaction, error)
+	DeleteTransaction(ctx context.Context, in *DeleteTransactionRequest, opts ...grpc.CallOption) (*Empty, error)
+	ListTransactions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ListTransactionsResponse, error)
+	RunTransaction(ctx context.Context, in *RunTransactionRequest, opts ...grpc.CallOption) (Replicant_RunTransactionClient, error)
+	GetStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*StatusResponse, error)
+}
+
+type replicantClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewReplicantClient builds a ReplicantClient that issues RPCs over cc.
+func NewReplicantClient(cc grpc.ClientConnInterface) ReplicantClient {
+	return &replicantClient{cc}
+}
+
+func (c *replicantClient) CreateTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (out *Transaction, err error) {
+	out = new(Transaction)
+	if err = c.cc.Invoke(ctx, "/replicant.v1.Replicant/CreateTransaction", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replicantClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (out *Transaction, err error) {
+	out = new(Transaction)
+	if err = c.cc.Invoke(ctx, "/replicant.v1.Replicant/GetTransaction", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replicantClient) DeleteTransaction(ctx context.Context, in *DeleteTransactionRequest, opts ...grpc.CallOption) (out *Empty, err error) {
+	out = new(Empty)
+	if err = c.cc.Invoke(ctx, "/replicant.v1.Replicant/DeleteTransaction", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replicantClient) ListTransactions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (out *ListTransactionsResponse, err error) {
+	out = new(ListTransactionsResponse)
+	if err = c.cc.Invoke(ctx, "/replicant.v1.Replicant/ListTransactions", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replicantClient) GetStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (out *StatusResponse, err error) {
+	out = new(StatusResponse)
+	if err = c.cc.Invoke(ctx, "/replicant.v1.Replicant/GetStatus", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replicantClient) RunTransaction(ctx context.Context, in *RunTransactionRequest, opts ...grpc.CallOption) (Replicant_RunTransactionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &replicantServiceDesc.Streams[0], "/replicant.v1.Replicant/RunTransaction", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &replicantRunTransactionClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// Replicant_RunTransactionClient receives the Result stream produced by a
+// single RunTransaction call.
+type Replicant_RunTransactionClient interface {
+	Recv() (*Result, error)
+	grpc.ClientStream
+}
+
+type replicantRunTransactionClient struct {
+	grpc.ClientStream
+}
+
+func (x *replicantRunTransactionClient) Recv() (*Result, error) {
+	m := new(Result)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func replicantCreateTransactionHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Transaction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicantServer).CreateTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/replicant.v1.Replicant/CreateTransaction"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicantServer).CreateTransaction(ctx, req.(*Transaction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func replicantGetTransactionHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicantServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/replicant.v1.Replicant/GetTransaction"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicantServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func replicantDeleteTransactionHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicantServer).DeleteTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/replicant.v1.Replicant/DeleteTransaction"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicantServer).DeleteTransaction(ctx, req.(*DeleteTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func replicantListTransactionsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicantServer).ListTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/replicant.v1.Replicant/ListTransactions"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicantServer).ListTransactions(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func replicantGetStatusHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicantServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/replicant.v1.Replicant/GetStatus"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicantServer).GetStatus(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func replicantRunTransactionHandler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(RunTransactionRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(ReplicantServer).RunTransaction(in, &replicantRunTransactionServer{stream})
+}
+
+var replicantServiceDesc = grpc.ServiceDesc{
+	ServiceName: "replicant.v1.Replicant",
+	HandlerType: (*ReplicantServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "CreateTransaction", Handler: replicantCreateTransactionHandler},
+		{MethodName: "GetTransaction", Handler: replicantGetTransactionHandler},
+		{MethodName: "DeleteTransaction", Handler: replicantDeleteTransactionHandler},
+		{MethodName: "ListTransactions", Handler: replicantListTransactionsHandler},
+		{MethodName: "GetStatus", Handler: replicantGetStatusHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RunTransaction",
+			Handler:       replicantRunTransactionHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "replicant.proto",
+}
@@ -0,0 +1,50 @@
+package grpc
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"github.com/brunotm/replicant/manager"
+	"github.com/brunotm/replicant/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// New builds a *grpc.Server exposing the replicant.v1 Replicant service
+// backed by m, reusing auth to authenticate calls the same way the REST
+// API does. The returned server satisfies server.GRPCHandler and should
+// be attached with Server.SetGRPCHandler.
+func New(m *manager.Manager, auth server.Authenticator, tlsConfig server.TLSConfig) (s *grpc.Server, err error) {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(unaryAuthInterceptor(auth)),
+		grpc.StreamInterceptor(streamAuthInterceptor(auth)),
+	}
+
+	if tlsConfig.Enabled {
+		cert, err := tls.LoadX509KeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("grpc: loading tls credentials: %w", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewServerTLSFromCert(&cert)))
+	}
+
+	s = grpc.NewServer(opts...)
+	RegisterReplicantServer(s, &service{manager: m})
+	return s, nil
+}
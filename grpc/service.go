@@ -0,0 +1,107 @@
+package grpc
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+
+	"github.com/brunotm/replicant/manager"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// service implements ReplicantServer against a manager.Manager, the same
+// one backing the REST API.
+type service struct {
+	manager *manager.Manager
+}
+
+func (s *service) CreateTransaction(ctx context.Context, t *Transaction) (out *Transaction, err error) {
+	if err = s.manager.Set(t.Name, t.Config); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return t, nil
+}
+
+func (s *service) GetTransaction(ctx context.Context, r *GetTransactionRequest) (t *Transaction, err error) {
+	config, err := s.manager.Get(r.Name)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &Transaction{Name: r.Name, Config: config}, nil
+}
+
+func (s *service) DeleteTransaction(ctx context.Context, r *DeleteTransactionRequest) (e *Empty, err error) {
+	if err = s.manager.Delete(r.Name); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &Empty{}, nil
+}
+
+func (s *service) ListTransactions(ctx context.Context, _ *Empty) (resp *ListTransactionsResponse, err error) {
+	names := s.manager.List()
+
+	resp = &ListTransactionsResponse{Transactions: make([]*Transaction, 0, len(names))}
+	for _, name := range names {
+		config, err := s.manager.Get(name)
+		if err != nil {
+			continue
+		}
+		resp.Transactions = append(resp.Transactions, &Transaction{Name: name, Config: config})
+	}
+
+	return resp, nil
+}
+
+// RunTransaction runs the requested transaction and streams each result
+// to the client as manager.Manager produces it, for live monitoring of
+// the run, instead of waiting for it to finish.
+func (s *service) RunTransaction(r *RunTransactionRequest, stream Replicant_RunTransactionServer) (err error) {
+	results, err := s.manager.Run(stream.Context(), r.Name)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+
+		case result, ok := <-results:
+			if !ok {
+				return nil
+			}
+
+			if err = stream.Send(&Result{
+				Name:      r.Name,
+				Output:    result.Output,
+				Error:     result.Error,
+				Timestamp: result.Timestamp,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *service) GetStatus(ctx context.Context, _ *Empty) (resp *StatusResponse, err error) {
+	st := s.manager.Status()
+	return &StatusResponse{
+		Transactions:  int32(st.Transactions),
+		UptimeSeconds: int64(st.Uptime.Seconds()),
+	}, nil
+}
@@ -0,0 +1,118 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrUnauthorized is returned by an Authenticator when the supplied
+// credentials do not grant access to the request.
+var ErrUnauthorized = errors.New("server: unauthorized")
+
+// Authenticator verifies the credentials carried by an incoming request.
+// Implementations must return ErrUnauthorized (or a wrapped error) when
+// the request should be rejected.
+type Authenticator interface {
+	Authenticate(r *http.Request) (err error)
+}
+
+// AuthenticatorFactory builds an Authenticator from the server Config.
+// Third-party authenticators can be added to the registry with
+// RegisterAuthenticator.
+type AuthenticatorFactory func(config Config) (auth Authenticator, err error)
+
+// AuthConfig configures the authentication backend used by a Server.
+type AuthConfig struct {
+	// Method selects the registered authenticator, one of:
+	// "", "none", "internal", "http" or "jwt".
+	Method   string         `json:"method" yaml:"method"`
+	Internal InternalConfig `json:"internal" yaml:"internal"`
+	HTTP     HTTPAuthConfig `json:"http" yaml:"http"`
+	JWT      JWTConfig      `json:"jwt" yaml:"jwt"`
+}
+
+var authenticators = map[string]AuthenticatorFactory{}
+
+// RegisterAuthenticator registers an authenticator factory under the given
+// method name, making it selectable through Config.Auth.Method. It is
+// meant to be called from an init() function, and panics on duplicate
+// registration.
+func RegisterAuthenticator(method string, factory AuthenticatorFactory) {
+	if _, exists := authenticators[method]; exists {
+		panic(fmt.Sprintf("server: authenticator %q already registered", method))
+	}
+	authenticators[method] = factory
+}
+
+func init() {
+	RegisterAuthenticator("internal", newInternalAuthenticator)
+	RegisterAuthenticator("http", newHTTPAuthenticator)
+	RegisterAuthenticator("jwt", newJWTAuthenticator)
+}
+
+// noneAuthenticator allows all requests through. It is used when no
+// authentication method is configured.
+type noneAuthenticator struct{}
+
+func (noneAuthenticator) Authenticate(r *http.Request) (err error) {
+	return nil
+}
+
+// newAuthenticator builds the Authenticator configured for this server.
+// A legacy Config.Username/Password pair without Auth.Method configured
+// is honored as an "internal" authenticator for backward compatibility.
+func newAuthenticator(config Config) (auth Authenticator, err error) {
+	method := config.Auth.Method
+
+	if method == "" && config.Username != "" && config.Password != "" {
+		method = "internal"
+		config.Auth.Internal.Users = append(config.Auth.Internal.Users,
+			User{Username: config.Username, Password: config.Password})
+	}
+
+	if method == "" || method == "none" {
+		return noneAuthenticator{}, nil
+	}
+
+	factory, ok := authenticators[method]
+	if !ok {
+		return nil, fmt.Errorf("server: unknown auth method %q", method)
+	}
+
+	return factory(config)
+}
+
+// SetAuthenticator overrides this server authenticator, allowing
+// third-party code to inject a custom implementation.
+func (s *Server) SetAuthenticator(auth Authenticator) {
+	s.auth = auth
+}
+
+// auth middleware enforces the server configured Authenticator, as
+// returned by a on each request.
+func auth(h Handler, a func() Authenticator) (n Handler) {
+	return func(w http.ResponseWriter, r *http.Request, p Params) {
+		if err := a().Authenticate(r); err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		h(w, r, p)
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HTTPAuthConfig configures the "http" authenticator, which delegates
+// authentication decisions to an external service.
+type HTTPAuthConfig struct {
+	URL string `json:"url" yaml:"url"`
+	// Method is the HTTP method used to call URL, defaults to "POST".
+	Method   string        `json:"method" yaml:"method"`
+	Timeout  time.Duration `json:"timeout" yaml:"timeout"`
+	CacheTTL time.Duration `json:"cache_ttl" yaml:"cache_ttl"`
+}
+
+type httpAuthCacheEntry struct {
+	ok      bool
+	expires time.Time
+}
+
+// httpAuthenticator authenticates requests by delegating the decision to
+// a configured URL, caching the outcome for CacheTTL keyed by a hash of
+// the request credentials.
+type httpAuthenticator struct {
+	config HTTPAuthConfig
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]httpAuthCacheEntry
+}
+
+func newHTTPAuthenticator(config Config) (a Authenticator, err error) {
+	if config.Auth.HTTP.URL == "" {
+		return nil, fmt.Errorf("server: http authenticator requires a url")
+	}
+
+	method := config.Auth.HTTP.Method
+	if method == "" {
+		method = http.MethodPost
+		config.Auth.HTTP.Method = method
+	}
+
+	timeout := config.Auth.HTTP.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &httpAuthenticator{
+		config: config.Auth.HTTP,
+		client: &http.Client{Timeout: timeout},
+		cache:  make(map[string]httpAuthCacheEntry),
+	}, nil
+}
+
+func (h *httpAuthenticator) Authenticate(r *http.Request) (err error) {
+	credentials := r.Header.Get("Authorization")
+	if credentials == "" {
+		return ErrUnauthorized
+	}
+
+	key := hashCredentials(credentials)
+
+	if h.config.CacheTTL > 0 {
+		if ok, found := h.fromCache(key); found {
+			if !ok {
+				return ErrUnauthorized
+			}
+			return nil
+		}
+	}
+
+	ok, err := h.authenticate(r, credentials)
+	if err != nil {
+		return fmt.Errorf("server: http authenticator: %w", err)
+	}
+
+	if h.config.CacheTTL > 0 {
+		h.toCache(key, ok)
+	}
+
+	if !ok {
+		return ErrUnauthorized
+	}
+
+	return nil
+}
+
+func (h *httpAuthenticator) authenticate(r *http.Request, credentials string) (ok bool, err error) {
+	req, err := http.NewRequest(h.config.Method, h.config.URL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", credentials)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode >= 200 && resp.StatusCode < 300, nil
+}
+
+func (h *httpAuthenticator) fromCache(key string) (ok, found bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entry, found := h.cache[key]
+	if !found {
+		return false, false
+	}
+
+	if time.Now().After(entry.expires) {
+		delete(h.cache, key)
+		return false, false
+	}
+
+	return entry.ok, true
+}
+
+func (h *httpAuthenticator) toCache(key string, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cache[key] = httpAuthCacheEntry{
+		ok:      ok,
+		expires: time.Now().Add(h.config.CacheTTL),
+	}
+}
+
+func hashCredentials(credentials string) string {
+	sum := sha256.Sum256([]byte(credentials))
+	return hex.EncodeToString(sum[:])
+}
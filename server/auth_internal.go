@@ -0,0 +1,95 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// User is an internal authenticator account.
+type User struct {
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+}
+
+// InternalConfig configures the "internal" authenticator, a static list
+// of users and an optional per-path access control list.
+type InternalConfig struct {
+	Users []User `json:"users" yaml:"users"`
+	// Permissions maps a request path prefix to the usernames allowed
+	// to access it. Paths without an entry are accessible to any
+	// authenticated user.
+	Permissions map[string][]string `json:"permissions" yaml:"permissions"`
+}
+
+// internalAuthenticator authenticates requests against a static list of
+// users loaded from Config, and enforces per-path ACLs.
+type internalAuthenticator struct {
+	users       map[string]string
+	permissions map[string][]string
+}
+
+func newInternalAuthenticator(config Config) (a Authenticator, err error) {
+	i := &internalAuthenticator{
+		users:       make(map[string]string, len(config.Auth.Internal.Users)),
+		permissions: config.Auth.Internal.Permissions,
+	}
+
+	for _, u := range config.Auth.Internal.Users {
+		i.users[u.Username] = u.Password
+	}
+
+	return i, nil
+}
+
+func (i *internalAuthenticator) Authenticate(r *http.Request) (err error) {
+	username, password, hasAuth := r.BasicAuth()
+	if !hasAuth {
+		return ErrUnauthorized
+	}
+
+	want, ok := i.users[username]
+	if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(want)) != 1 {
+		return ErrUnauthorized
+	}
+
+	if allowed, ok := i.permissionsFor(r.URL.Path); ok && !contains(allowed, username) {
+		return ErrUnauthorized
+	}
+
+	return nil
+}
+
+func (i *internalAuthenticator) permissionsFor(path string) (allowed []string, ok bool) {
+	for prefix, users := range i.permissions {
+		if strings.HasPrefix(path, prefix) {
+			return users, true
+		}
+	}
+	return nil, false
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func newTestInternalAuthenticator(t *testing.T) Authenticator {
+	t.Helper()
+
+	auth, err := newInternalAuthenticator(Config{
+		Auth: AuthConfig{
+			Internal: InternalConfig{
+				Users: []User{
+					{Username: "alice", Password: "secret"},
+					{Username: "bob", Password: "hunter2"},
+				},
+				Permissions: map[string][]string{
+					"/api/admin": {"alice"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("newInternalAuthenticator: %v", err)
+	}
+
+	return auth
+}
+
+func authRequest(method, username, password, path string) *http.Request {
+	r := &http.Request{
+		Method: method,
+		URL:    &url.URL{Path: path},
+		Header: http.Header{},
+	}
+
+	if username != "" || password != "" {
+		r.SetBasicAuth(username, password)
+	}
+
+	return r
+}
+
+func TestInternalAuthenticatorAuthenticate(t *testing.T) {
+	auth := newTestInternalAuthenticator(t)
+
+	cases := []struct {
+		name     string
+		username string
+		password string
+		path     string
+		wantErr  error
+	}{
+		{name: "valid credentials", username: "alice", password: "secret", path: "/api/config", wantErr: nil},
+		{name: "wrong password", username: "alice", password: "wrong", path: "/api/config", wantErr: ErrUnauthorized},
+		{name: "unknown user", username: "eve", password: "secret", path: "/api/config", wantErr: ErrUnauthorized},
+		{name: "missing credentials", username: "", password: "", path: "/api/config", wantErr: ErrUnauthorized},
+		{name: "allowed by ACL", username: "alice", password: "secret", path: "/api/admin/users", wantErr: nil},
+		{name: "denied by ACL", username: "bob", password: "hunter2", path: "/api/admin/users", wantErr: ErrUnauthorized},
+		{name: "unrestricted path", username: "bob", password: "hunter2", path: "/api/config", wantErr: nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := auth.Authenticate(authRequest(http.MethodGet, c.username, c.password, c.path))
+			if err != c.wantErr {
+				t.Fatalf("Authenticate() = %v, want %v", err, c.wantErr)
+			}
+		})
+	}
+}
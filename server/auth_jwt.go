@@ -0,0 +1,170 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// JWTConfig configures the "jwt" authenticator.
+type JWTConfig struct {
+	// Secret is the shared key used to verify HS256/HS384/HS512 tokens.
+	Secret string `json:"secret" yaml:"secret"`
+	// JWKSURL, when set, is periodically fetched to verify RS256/ES256
+	// tokens against the published key set instead of a static Secret.
+	JWKSURL     string        `json:"jwks_url" yaml:"jwks_url"`
+	JWKSRefresh time.Duration `json:"jwks_refresh" yaml:"jwks_refresh"`
+	Issuer      string        `json:"issuer" yaml:"issuer"`
+	Audience    string        `json:"audience" yaml:"audience"`
+	Algorithms  []string      `json:"algorithms" yaml:"algorithms"`
+}
+
+// jwtAuthenticator authenticates requests carrying a `Bearer` JWT in the
+// Authorization header.
+type jwtAuthenticator struct {
+	config JWTConfig
+	algs   map[string]bool
+
+	jwks *jwksCache
+}
+
+func newJWTAuthenticator(config Config) (a Authenticator, err error) {
+	cfg := config.Auth.JWT
+	if cfg.Secret == "" && cfg.JWKSURL == "" {
+		return nil, fmt.Errorf("server: jwt authenticator requires a secret or jwks_url")
+	}
+
+	algs := make(map[string]bool, len(cfg.Algorithms))
+	for _, alg := range cfg.Algorithms {
+		algs[alg] = true
+	}
+
+	j := &jwtAuthenticator{config: cfg, algs: algs}
+
+	if cfg.JWKSURL != "" {
+		refresh := cfg.JWKSRefresh
+		if refresh == 0 {
+			refresh = 10 * time.Minute
+		}
+		j.jwks = newJWKSCache(cfg.JWKSURL, refresh)
+	}
+
+	return j, nil
+}
+
+func (j *jwtAuthenticator) Authenticate(r *http.Request) (err error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ErrUnauthorized
+	}
+	raw := strings.TrimPrefix(header, "Bearer ")
+
+	token, err := jwt.Parse(raw, j.keyFunc)
+	if err != nil || !token.Valid {
+		return ErrUnauthorized
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ErrUnauthorized
+	}
+
+	if j.config.Issuer != "" && !claims.VerifyIssuer(j.config.Issuer, true) {
+		return ErrUnauthorized
+	}
+
+	if j.config.Audience != "" && !claims.VerifyAudience(j.config.Audience, true) {
+		return ErrUnauthorized
+	}
+
+	now := time.Now().Unix()
+	if !claims.VerifyExpiresAt(now, true) {
+		return ErrUnauthorized
+	}
+	if !claims.VerifyNotBefore(now, false) {
+		return ErrUnauthorized
+	}
+
+	return nil
+}
+
+func (j *jwtAuthenticator) keyFunc(token *jwt.Token) (key interface{}, err error) {
+	if len(j.algs) > 0 && !j.algs[token.Method.Alg()] {
+		return nil, fmt.Errorf("server: unexpected jwt signing method %q", token.Method.Alg())
+	}
+
+	if j.jwks != nil {
+		kid, _ := token.Header["kid"].(string)
+		return j.jwks.key(kid)
+	}
+
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return []byte(j.config.Secret), nil
+	default:
+		return nil, fmt.Errorf("server: no key configured for signing method %q", token.Method.Alg())
+	}
+}
+
+// jwksCache fetches and periodically refreshes a JSON Web Key Set.
+type jwksCache struct {
+	url     string
+	refresh time.Duration
+
+	mu      sync.Mutex
+	keys    map[string]interface{}
+	fetched time.Time
+}
+
+func newJWKSCache(url string, refresh time.Duration) (c *jwksCache) {
+	return &jwksCache{url: url, refresh: refresh, keys: make(map[string]interface{})}
+}
+
+func (c *jwksCache) key(kid string) (key interface{}, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.fetched) > c.refresh {
+		if err := c.fetchLocked(); err != nil {
+			return nil, err
+		}
+	}
+
+	key, ok := c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("server: unknown jwks key id %q", kid)
+	}
+
+	return key, nil
+}
+
+func (c *jwksCache) fetchLocked() (err error) {
+	keys, err := fetchJWKS(c.url)
+	if err != nil {
+		return err
+	}
+
+	c.keys = keys
+	c.fetched = time.Now()
+	return nil
+}
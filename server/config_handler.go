@@ -0,0 +1,266 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrFingerprintMismatch is returned by DoLockedAction when the supplied
+// fingerprint no longer matches the current config, so the caller should
+// re-read it and retry.
+var ErrFingerprintMismatch = errors.New("server: config fingerprint mismatch")
+
+// ErrImmutableField is returned when an update attempts to change a
+// config field that cannot take effect without restarting the server.
+var ErrImmutableField = errors.New("server: immutable config field")
+
+// ConfigHandler exposes a Server's Config as a live, hot-reloadable
+// object. Reads are always consistent; writes are guarded by
+// Fingerprint so concurrent updates can detect and reject stale writes.
+type ConfigHandler interface {
+	// Fingerprint returns a stable hash of the current marshaled config.
+	Fingerprint() (fingerprint string)
+	MarshalJSON() (data []byte, err error)
+	UnmarshalJSON(data []byte) (err error)
+	UnmarshalYAML(unmarshal func(interface{}) error) (err error)
+	// MarshalJSONPath returns the sub-document at the given RFC-6901
+	// JSON pointer, e.g. "/auth/method".
+	MarshalJSONPath(path string) (data []byte, err error)
+	// UnmarshalJSONPath replaces the sub-document at path with data.
+	UnmarshalJSONPath(path string, data []byte) (err error)
+	// DoLockedAction runs cb only if fingerprint still matches the
+	// current config, implementing optimistic concurrency for updates.
+	DoLockedAction(fingerprint string, cb func(ConfigHandler) error) (err error)
+}
+
+// liveConfig is the Server's ConfigHandler implementation.
+type liveConfig struct {
+	mu     sync.RWMutex
+	server *Server
+	config Config
+}
+
+func newLiveConfig(s *Server, config Config) (c *liveConfig) {
+	return &liveConfig{server: s, config: config}
+}
+
+func (c *liveConfig) Fingerprint() (fingerprint string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return configFingerprint(c.config)
+}
+
+func configFingerprint(config Config) (fingerprint string) {
+	// Errors are not possible here, Config is always JSON serializable.
+	data, _ := json.Marshal(config)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *liveConfig) MarshalJSON() (data []byte, err error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return json.Marshal(c.config)
+}
+
+func (c *liveConfig) UnmarshalJSON(data []byte) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.unmarshalJSONLocked(data)
+}
+
+// unmarshalJSONLocked is UnmarshalJSON's body, assuming c.mu is already
+// held for writing. Used directly by DoLockedAction's callback so the
+// fingerprint check and the mutation happen under a single critical
+// section.
+func (c *liveConfig) unmarshalJSONLocked(data []byte) (err error) {
+	var cfg Config
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("server: decoding config: %w", err)
+	}
+
+	return c.applyLocked(cfg)
+}
+
+func (c *liveConfig) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.unmarshalYAMLLocked(unmarshal)
+}
+
+func (c *liveConfig) unmarshalYAMLLocked(unmarshal func(interface{}) error) (err error) {
+	var cfg Config
+	if err = unmarshal(&cfg); err != nil {
+		return fmt.Errorf("server: decoding config: %w", err)
+	}
+
+	return c.applyLocked(cfg)
+}
+
+func (c *liveConfig) MarshalJSONPath(path string) (data []byte, err error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.marshalJSONPathLocked(path)
+}
+
+func (c *liveConfig) marshalJSONPathLocked(path string) (data []byte, err error) {
+	doc, err := toJSONDoc(c.config)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := jsonPointerGet(doc, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+func (c *liveConfig) UnmarshalJSONPath(path string, data []byte) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.unmarshalJSONPathLocked(path, data)
+}
+
+func (c *liveConfig) unmarshalJSONPathLocked(path string, data []byte) (err error) {
+	var value interface{}
+	if err = json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("server: decoding config path %q: %w", path, err)
+	}
+
+	doc, err := toJSONDoc(c.config)
+	if err != nil {
+		return err
+	}
+
+	if doc, err = jsonPointerSet(doc, path, value); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("server: encoding config: %w", err)
+	}
+
+	var cfg Config
+	if err = json.Unmarshal(merged, &cfg); err != nil {
+		return fmt.Errorf("server: decoding config: %w", err)
+	}
+
+	return c.applyLocked(cfg)
+}
+
+// DoLockedAction holds c.mu for the entire compare-and-call critical
+// section: the fingerprint check and cb run atomically, so two
+// concurrent callers supplying the same fingerprint cannot both
+// succeed. cb receives a ConfigHandler whose mutating methods assume
+// the lock is already held, rather than c itself, to avoid deadlocking
+// by re-acquiring c.mu.
+func (c *liveConfig) DoLockedAction(fingerprint string, cb func(ConfigHandler) error) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if fingerprint != configFingerprint(c.config) {
+		return ErrFingerprintMismatch
+	}
+
+	return cb(&lockedConfigHandler{c})
+}
+
+// lockedConfigHandler adapts liveConfig's *Locked methods to the
+// ConfigHandler interface for use inside DoLockedAction, where c.mu is
+// already held by the caller.
+type lockedConfigHandler struct {
+	c *liveConfig
+}
+
+func (l *lockedConfigHandler) Fingerprint() (fingerprint string) {
+	return configFingerprint(l.c.config)
+}
+
+func (l *lockedConfigHandler) MarshalJSON() (data []byte, err error) {
+	return json.Marshal(l.c.config)
+}
+
+func (l *lockedConfigHandler) UnmarshalJSON(data []byte) (err error) {
+	return l.c.unmarshalJSONLocked(data)
+}
+
+func (l *lockedConfigHandler) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	return l.c.unmarshalYAMLLocked(unmarshal)
+}
+
+func (l *lockedConfigHandler) MarshalJSONPath(path string) (data []byte, err error) {
+	return l.c.marshalJSONPathLocked(path)
+}
+
+func (l *lockedConfigHandler) UnmarshalJSONPath(path string, data []byte) (err error) {
+	return l.c.unmarshalJSONPathLocked(path, data)
+}
+
+func (l *lockedConfigHandler) DoLockedAction(fingerprint string, cb func(ConfigHandler) error) (err error) {
+	return fmt.Errorf("server: nested DoLockedAction is not supported")
+}
+
+// applyLocked validates cfg against the immutable fields of the current
+// config, applies it to the running server and, on success, stores it as
+// the current config. c.mu must be held for writing.
+func (c *liveConfig) applyLocked(cfg Config) (err error) {
+	if cfg.ListenAddress != c.config.ListenAddress {
+		return fmt.Errorf("%w: listen_address cannot change without a restart", ErrImmutableField)
+	}
+
+	if !reflect.DeepEqual(cfg.TLS, c.config.TLS) {
+		return fmt.Errorf("%w: tls cannot change without a restart", ErrImmutableField)
+	}
+
+	if cfg.GRPCListenAddress != c.config.GRPCListenAddress {
+		return fmt.Errorf("%w: grpc_listen_address cannot change without a restart", ErrImmutableField)
+	}
+
+	if !reflect.DeepEqual(cfg.GRPCTLS, c.config.GRPCTLS) {
+		return fmt.Errorf("%w: grpc_tls cannot change without a restart", ErrImmutableField)
+	}
+
+	if err = c.server.applyConfig(cfg); err != nil {
+		return err
+	}
+
+	c.config = cfg
+	return nil
+}
+
+func toJSONDoc(v interface{}) (doc interface{}, err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("server: encoding config: %w", err)
+	}
+
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("server: decoding config: %w", err)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,116 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	s, err := New(Config{MaxRequestsInFlight: 1}, nil, httprouter.New())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	return s
+}
+
+// TestDoLockedActionConcurrentSameFingerprint races two callers presenting
+// the same fingerprint against DoLockedAction. Exactly one must succeed;
+// the other must see ErrFingerprintMismatch rather than both mutating the
+// config.
+func TestDoLockedActionConcurrentSameFingerprint(t *testing.T) {
+	s := newTestServer(t)
+	c := s.ConfigHandler()
+	fingerprint := c.Fingerprint()
+
+	var wg sync.WaitGroup
+	results := make([]error, 2)
+
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = c.DoLockedAction(fingerprint, func(locked ConfigHandler) error {
+				return locked.UnmarshalJSONPath("/max_requests_in_flight", []byte("2"))
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	successes := 0
+	mismatches := 0
+	for _, err := range results {
+		switch err {
+		case nil:
+			successes++
+		case ErrFingerprintMismatch:
+			mismatches++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if successes != 1 || mismatches != 1 {
+		t.Fatalf("got %d successes and %d mismatches, want exactly 1 of each", successes, mismatches)
+	}
+}
+
+func TestDoLockedActionRejectsGRPCListenAddressChange(t *testing.T) {
+	s := newTestServer(t)
+	c := s.ConfigHandler()
+	fingerprint := c.Fingerprint()
+
+	err := c.DoLockedAction(fingerprint, func(locked ConfigHandler) error {
+		return locked.UnmarshalJSONPath("/grpc_listen_address", []byte(`":9090"`))
+	})
+	if !errors.Is(err, ErrImmutableField) {
+		t.Fatalf("DoLockedAction() = %v, want %v", err, ErrImmutableField)
+	}
+}
+
+func TestDoLockedActionRejectsGRPCTLSChange(t *testing.T) {
+	s := newTestServer(t)
+	c := s.ConfigHandler()
+	fingerprint := c.Fingerprint()
+
+	err := c.DoLockedAction(fingerprint, func(locked ConfigHandler) error {
+		return locked.UnmarshalJSONPath("/grpc_tls/enabled", []byte("true"))
+	})
+	if !errors.Is(err, ErrImmutableField) {
+		t.Fatalf("DoLockedAction() = %v, want %v", err, ErrImmutableField)
+	}
+}
+
+func TestDoLockedActionMismatch(t *testing.T) {
+	s := newTestServer(t)
+	c := s.ConfigHandler()
+
+	err := c.DoLockedAction("stale-fingerprint", func(locked ConfigHandler) error {
+		t.Fatal("cb must not run when the fingerprint does not match")
+		return nil
+	})
+	if err != ErrFingerprintMismatch {
+		t.Fatalf("DoLockedAction() = %v, want %v", err, ErrFingerprintMismatch)
+	}
+}
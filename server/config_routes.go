@@ -0,0 +1,139 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/brunotm/replicant/log"
+	"gopkg.in/yaml.v2"
+)
+
+// registerConfigRoutes wires the hot-reload config API onto the server
+// router: GET /api/config, GET /api/config/*path and
+// PATCH /api/config/*path.
+func (s *Server) registerConfigRoutes() {
+	s.AddHandler(http.MethodGet, "/api/config", s.handleGetConfig)
+	s.AddHandler(http.MethodGet, "/api/config/*path", s.handleGetConfigPath)
+	s.AddHandler(http.MethodPatch, "/api/config/*path", s.handlePatchConfigPath)
+}
+
+func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request, p Params) {
+	data, err := s.configHandler.MarshalJSON()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("ETag", s.configHandler.Fingerprint())
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (s *Server) handleGetConfigPath(w http.ResponseWriter, r *http.Request, p Params) {
+	data, err := s.configHandler.MarshalJSONPath(p.ByName("path"))
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, err)
+		return
+	}
+
+	w.Header().Set("ETag", s.configHandler.Fingerprint())
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (s *Server) handlePatchConfigPath(w http.ResponseWriter, r *http.Request, p Params) {
+	fingerprint := r.Header.Get("If-Match")
+	if fingerprint == "" {
+		writeJSONError(w, http.StatusPreconditionRequired, errors.New("server: missing If-Match header"))
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	path := p.ByName("path")
+	err = s.configHandler.DoLockedAction(fingerprint, func(c ConfigHandler) error {
+		return c.UnmarshalJSONPath(path, body)
+	})
+
+	switch {
+	case errors.Is(err, ErrFingerprintMismatch):
+		writeJSONError(w, http.StatusConflict, err)
+	case errors.Is(err, ErrImmutableField):
+		writeJSONError(w, http.StatusUnprocessableEntity, err)
+	case err != nil:
+		writeJSONError(w, http.StatusBadRequest, err)
+	default:
+		w.Header().Set("ETag", s.configHandler.Fingerprint())
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    status,
+			"message": err.Error(),
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonBody)
+}
+
+// WatchConfigFile rereads path and applies it to this server's config
+// whenever the process receives SIGHUP. The file is decoded as YAML or
+// JSON based on its extension.
+func (s *Server) WatchConfigFile(path string) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+
+	go func() {
+		for range sig {
+			if err := s.reloadConfigFile(path); err != nil {
+				log.Error("reloading config").String("path", path).String("error", err.Error()).Log()
+				continue
+			}
+			log.Info("reloaded config").String("path", path).Log()
+		}
+	}()
+}
+
+func (s *Server) reloadConfigFile(path string) (err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+		return yaml.Unmarshal(data, s.configHandler)
+	}
+
+	return s.configHandler.UnmarshalJSON(data)
+}
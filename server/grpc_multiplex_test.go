@@ -0,0 +1,90 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/net/http2"
+)
+
+// fakeGRPCHandler implements GRPCHandler without depending on a real
+// google.golang.org/grpc.Server, just to observe whether a request reached
+// it.
+type fakeGRPCHandler struct {
+	served chan struct{}
+}
+
+func (f *fakeGRPCHandler) Serve(lis net.Listener) (err error) { return nil }
+func (f *fakeGRPCHandler) GracefulStop()                      {}
+func (f *fakeGRPCHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	close(f.served)
+}
+
+// TestSetGRPCHandlerServesCleartextHTTP2 multiplexes a fake gRPC handler
+// onto the main HTTP port with TLS disabled and drives a real cleartext
+// HTTP/2 request at it, the same way grpc.Dial does by default. Without
+// h2c support, a plain http.Server cannot negotiate HTTP/2 without TLS and
+// this request would instead hang until the client's deadline.
+func TestSetGRPCHandlerServesCleartextHTTP2(t *testing.T) {
+	s, err := New(Config{}, nil, httprouter.New())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	fake := &fakeGRPCHandler{served: make(chan struct{})}
+	s.SetGRPCHandler(fake)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer lis.Close()
+
+	go s.http.Serve(lis)
+
+	transport := &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+lis.Addr().String()+"/replicant.v1.Replicant/GetStatus", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	resp.Body.Close()
+
+	select {
+	case <-fake.served:
+	default:
+		t.Fatal("request was not dispatched to the gRPC handler")
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPointerGet resolves the RFC-6901 JSON pointer path against doc,
+// a tree of map[string]interface{}/[]interface{} as produced by
+// encoding/json. An empty path returns doc itself.
+func jsonPointerGet(doc interface{}, path string) (value interface{}, err error) {
+	tokens, err := splitJSONPointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value = doc
+	for _, token := range tokens {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("server: config path %q: no such field %q", path, token)
+			}
+			value = v
+
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("server: config path %q: invalid array index %q", path, token)
+			}
+			value = node[index]
+
+		default:
+			return nil, fmt.Errorf("server: config path %q: cannot descend into scalar at %q", path, token)
+		}
+	}
+
+	return value, nil
+}
+
+// jsonPointerSet replaces the sub-document at path with value, modifying
+// doc in place, and returns doc. An empty path returns value instead.
+func jsonPointerSet(doc interface{}, path string, value interface{}) (out interface{}, err error) {
+	tokens, err := splitJSONPointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	parent, err := jsonPointerGet(doc, pointerPrefix(tokens[:len(tokens)-1]))
+	if err != nil {
+		return nil, err
+	}
+
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+
+	case []interface{}:
+		index, err := strconv.Atoi(last)
+		if err != nil || index < 0 || index >= len(node) {
+			return nil, fmt.Errorf("server: config path %q: invalid array index %q", path, last)
+		}
+		node[index] = value
+
+	default:
+		return nil, fmt.Errorf("server: config path %q: cannot descend into scalar at %q", path, last)
+	}
+
+	return doc, nil
+}
+
+func splitJSONPointer(path string) (tokens []string, err error) {
+	if path == "" || path == "/" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("server: invalid config path %q: must start with '/'", path)
+	}
+
+	for _, token := range strings.Split(path[1:], "/") {
+		token = strings.ReplaceAll(token, "~1", "/")
+		token = strings.ReplaceAll(token, "~0", "~")
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+func pointerPrefix(tokens []string) (path string) {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(tokens, "/")
+}
@@ -0,0 +1,121 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+)
+
+// jsonWebKey is a single entry of a JSON Web Key Set, as fetched from an
+// authenticator JWKSURL.
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// fetchJWKS retrieves and decodes the key set at url into a map of key
+// id to public key.
+func fetchJWKS(url string) (keys map[string]interface{}, err error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("server: fetching jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var set jsonWebKeySet
+	if err = json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("server: decoding jwks: %w", err)
+	}
+
+	keys = make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, err
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (key interface{}, err error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64URLBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64URLBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		x, err := base64URLBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64URLBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("server: unsupported jwks curve %q", k.Crv)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("server: unsupported jwks key type %q", k.Kty)
+	}
+}
+
+func base64URLBigInt(s string) (i *big.Int, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("server: decoding jwks key component: %w", err)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
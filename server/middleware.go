@@ -0,0 +1,100 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// timeoutBody is the JSON response written when a handler exceeds its
+// configured timeout.
+const timeoutBody = `{"error":{"code":503,"message":"request timeout"}}`
+
+// Options customize how a handler is added to the server, overriding the
+// server wide defaults.
+type Options struct {
+	// Timeout bounds how long the handler may run before it is aborted
+	// with a 503. Zero disables the per-route timeout.
+	Timeout time.Duration
+	// LongRunning exempts this handler from the in-flight request limit,
+	// regardless of Config.LongRunningRequestRE.
+	LongRunning bool
+}
+
+// withTimeout wraps h with http.TimeoutHandler, bounding its execution to
+// d. A zero duration disables the timeout.
+func withTimeout(h Handler, d time.Duration) (n Handler) {
+	if d <= 0 {
+		return h
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p Params) {
+		// http.TimeoutHandler writes the 503 directly to w, bypassing
+		// whatever the wrapped handler may have set on its own
+		// ResponseWriter, so default the content type here; a handler
+		// that completes in time still overrides it with its own.
+		w.Header().Set("Content-Type", "application/json")
+
+		inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h(w, r, p)
+		})
+
+		http.TimeoutHandler(inner, d, timeoutBody).ServeHTTP(w, r)
+	}
+}
+
+// limit enforces the server wide in-flight request cap, exempting paths
+// matched by Config.LongRunningRequestRE or marked LongRunning in Options.
+func (s *Server) limit(h Handler, longRunning bool) (n Handler) {
+	return func(w http.ResponseWriter, r *http.Request, p Params) {
+		// Read the limiter once per request: applyConfig may replace it,
+		// and the slot must be released on the channel it was taken from.
+		inFlight := s.inFlight
+		if inFlight == nil || longRunning || (s.longRunningRE != nil && s.longRunningRE.MatchString(r.URL.Path)) {
+			h(w, r, p)
+			return
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+			atomic.AddInt64(&s.inFlightGauge, 1)
+			defer func() {
+				<-inFlight
+				atomic.AddInt64(&s.inFlightGauge, -1)
+			}()
+			h(w, r, p)
+
+		default:
+			w.Header().Set("Retry-After", "1")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"code":429,"message":"too many requests in flight"}}`))
+		}
+	}
+}
+
+// metrics serves the current in-flight request gauge in the Prometheus
+// text exposition format.
+func (s *Server) metrics(w http.ResponseWriter, r *http.Request, p Params) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# HELP replicant_server_in_flight_requests Current number of in-flight HTTP requests.\n")
+	fmt.Fprintf(w, "# TYPE replicant_server_in_flight_requests gauge\n")
+	fmt.Fprintf(w, "replicant_server_in_flight_requests %d\n", atomic.LoadInt64(&s.inFlightGauge))
+}
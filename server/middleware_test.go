@@ -0,0 +1,102 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithTimeoutSetsJSONContentType(t *testing.T) {
+	blocked := make(chan struct{})
+	h := withTimeout(func(w http.ResponseWriter, r *http.Request, p Params) {
+		<-blocked
+	}, time.Millisecond)
+	defer close(blocked)
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", got)
+	}
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestWithTimeoutDisabledWhenZero(t *testing.T) {
+	called := false
+	h := withTimeout(func(w http.ResponseWriter, r *http.Request, p Params) {
+		called = true
+	}, 0)
+
+	h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+	if !called {
+		t.Fatal("handler was not called when Timeout is 0")
+	}
+}
+
+func TestLimitRejectsWhenFull(t *testing.T) {
+	s := &Server{inFlight: make(chan struct{}, 1)}
+
+	release := make(chan struct{})
+	h := s.limit(func(w http.ResponseWriter, r *http.Request, p Params) {
+		<-release
+	}, false)
+
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		h(first, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+		close(done)
+	}()
+
+	// Give the first request time to claim the only in-flight slot.
+	for len(s.inFlight) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	second := httptest.NewRecorder()
+	h(second, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+	if second.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want %d", second.Code, http.StatusTooManyRequests)
+	}
+
+	close(release)
+	<-done
+}
+
+func TestLimitExemptsLongRunning(t *testing.T) {
+	s := &Server{inFlight: make(chan struct{}, 1)}
+	s.inFlight <- struct{}{}
+
+	called := false
+	h := s.limit(func(w http.ResponseWriter, r *http.Request, p Params) {
+		called = true
+	}, true)
+
+	h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+	if !called {
+		t.Fatal("long-running handler was rejected despite the in-flight limit being full")
+	}
+}
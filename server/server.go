@@ -20,7 +20,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"regexp"
 	"runtime/debug"
+	"strings"
 
 	"net/http"
 	"time"
@@ -28,6 +31,8 @@ import (
 	"github.com/brunotm/replicant/log"
 	"github.com/brunotm/replicant/manager"
 	"github.com/julienschmidt/httprouter"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // Config for replicant server
@@ -38,14 +43,66 @@ type Config struct {
 	WriteTimeout      time.Duration `json:"write_timeout" yaml:"write_timeout"`
 	ReadTimeout       time.Duration `json:"read_timeout" yaml:"read_timeout"`
 	ReadHeaderTimeout time.Duration `json:"read_header_timeout" yaml:"read_header_timeout"`
+	Auth              AuthConfig    `json:"auth" yaml:"auth"`
+	// MaxRequestsInFlight bounds the number of requests served concurrently, zero disables the limit.
+	MaxRequestsInFlight int `json:"max_requests_in_flight" yaml:"max_requests_in_flight"`
+	// LongRunningRequestRE exempts matching URL paths (e.g. SSE or websocket endpoints) from the in-flight count.
+	LongRunningRequestRE string    `json:"long_running_request_re" yaml:"long_running_request_re"`
+	TLS                  TLSConfig `json:"tls" yaml:"tls"`
+	// GRPCListenAddress, when set and different from ListenAddress, serves the gRPC handler set through
+	// SetGRPCHandler on its own listener. When unset, the gRPC handler is multiplexed onto the main HTTP
+	// listener based on the request content-type.
+	GRPCListenAddress string    `json:"grpc_listen_address" yaml:"grpc_listen_address"`
+	GRPCTLS           TLSConfig `json:"grpc_tls" yaml:"grpc_tls"`
 }
 
 // Server is an replicant manager and api server
 type Server struct {
-	config  Config
-	http    *http.Server
-	router  *httprouter.Router
-	manager *manager.Manager
+	config        Config
+	http          *http.Server
+	router        *httprouter.Router
+	manager       *manager.Manager
+	auth          Authenticator
+	inFlight      chan struct{}
+	inFlightGauge int64
+	longRunningRE *regexp.Regexp
+	tlsCertFile   string
+	tlsKeyFile    string
+	acmeHTTP      *http.Server
+	configHandler *liveConfig
+	grpc          GRPCHandler
+	grpcAddr      string
+	grpcListener  net.Listener
+}
+
+// GRPCHandler serves gRPC requests, either on its own listener or
+// multiplexed onto the main HTTP port. Satisfied by *grpc.Server from
+// google.golang.org/grpc; wired in through Server.SetGRPCHandler.
+type GRPCHandler interface {
+	Serve(lis net.Listener) (err error)
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+	GracefulStop()
+}
+
+// SetGRPCHandler attaches a gRPC handler to this server, allowing the
+// grpc subpackage to inject itself without server importing it back.
+func (s *Server) SetGRPCHandler(handler GRPCHandler) {
+	s.grpc = handler
+
+	if s.grpcAddr == "" && s.http.TLSConfig == nil && s.tlsCertFile == "" {
+		// Multiplexing gRPC onto the main HTTP port relies on HTTP/2, but
+		// a plain http.Server only negotiates HTTP/2 over TLS (ALPN).
+		// Without TLS, wrap the handler with h2c so cleartext HTTP/2
+		// requests (what grpc.Dial sends by default) are served instead
+		// of hanging until the client's handshake deadline.
+		s.http.Handler = h2c.NewHandler(s, &http2.Server{})
+	}
+}
+
+// isGRPCRequest reports whether r carries a gRPC payload, as opposed to
+// a regular REST request on the same port.
+func isGRPCRequest(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
 }
 
 // New creates a new replicant server
@@ -69,13 +126,103 @@ func New(config Config, m *manager.Manager, r *httprouter.Router) (server *Serve
 		server.http.ReadHeaderTimeout = config.ReadHeaderTimeout
 	}
 
-	server.http.Handler = server.router
+	if server.auth, err = newAuthenticator(config); err != nil {
+		return nil, err
+	}
+
+	if config.MaxRequestsInFlight > 0 {
+		server.inFlight = make(chan struct{}, config.MaxRequestsInFlight)
+	}
+
+	if config.LongRunningRequestRE != "" {
+		if server.longRunningRE, err = regexp.Compile(config.LongRunningRequestRE); err != nil {
+			return nil, fmt.Errorf("server: invalid long_running_request_re: %w", err)
+		}
+	}
+
+	if err = server.configureTLS(config.TLS); err != nil {
+		return nil, err
+	}
+
+	server.grpcAddr = config.GRPCListenAddress
+	server.http.Handler = server
+	server.router.Handle(http.MethodGet, "/metrics", logger(server.metrics))
+
+	server.configHandler = newLiveConfig(server, config)
+	server.registerConfigRoutes()
 	return server, nil
 }
 
+// ConfigHandler returns this server's live, hot-reloadable config.
+func (s *Server) ConfigHandler() (c ConfigHandler) {
+	return s.configHandler
+}
+
+// applyConfig re-derives the server's runtime state (authenticator,
+// timeouts and in-flight limiter) from cfg. It must only be called with
+// a cfg whose immutable fields match the server's current config.
+func (s *Server) applyConfig(cfg Config) (err error) {
+	auth, err := newAuthenticator(cfg)
+	if err != nil {
+		return err
+	}
+
+	var longRunningRE *regexp.Regexp
+	if cfg.LongRunningRequestRE != "" {
+		if longRunningRE, err = regexp.Compile(cfg.LongRunningRequestRE); err != nil {
+			return fmt.Errorf("server: invalid long_running_request_re: %w", err)
+		}
+	}
+
+	var inFlight chan struct{}
+	if cfg.MaxRequestsInFlight > 0 {
+		inFlight = make(chan struct{}, cfg.MaxRequestsInFlight)
+	}
+
+	s.auth = auth
+	s.longRunningRE = longRunningRE
+	s.inFlight = inFlight
+	s.http.ReadTimeout = cfg.ReadTimeout
+	s.http.WriteTimeout = cfg.WriteTimeout
+	s.http.ReadHeaderTimeout = cfg.ReadHeaderTimeout
+	s.config = cfg
+	return nil
+}
+
 // Start serving
 func (s *Server) Start() (err error) {
-	if err = s.http.ListenAndServe(); err != http.ErrServerClosed {
+	if s.acmeHTTP != nil {
+		go func() {
+			if err := s.acmeHTTP.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Error("acme http server error").String("error", err.Error()).Log()
+			}
+		}()
+	}
+
+	if s.grpc != nil && s.grpcAddr != "" {
+		if s.grpcListener, err = net.Listen("tcp", s.grpcAddr); err != nil {
+			return fmt.Errorf("server: error starting grpc listener: %w", err)
+		}
+
+		go func() {
+			if err := s.grpc.Serve(s.grpcListener); err != nil {
+				log.Error("grpc server error").String("error", err.Error()).Log()
+			}
+		}()
+	}
+
+	switch {
+	case s.http.TLSConfig != nil:
+		// Certificates are served through autocert's GetCertificate,
+		// so CertFile/KeyFile are left empty.
+		err = s.http.ListenAndServeTLS("", "")
+	case s.tlsCertFile != "" && s.tlsKeyFile != "":
+		err = s.http.ListenAndServeTLS(s.tlsCertFile, s.tlsKeyFile)
+	default:
+		err = s.http.ListenAndServe()
+	}
+
+	if err != http.ErrServerClosed {
 		return fmt.Errorf("server: error starting http: %w", err)
 	}
 	return nil
@@ -91,41 +238,61 @@ func (s *Server) Manager() (m *manager.Manager) {
 	return s.manager
 }
 
-// ServeHTTP implements the http.Handler interface for testing and handler usage
+// ServeHTTP implements the http.Handler interface for testing and handler
+// usage, and multiplexes gRPC traffic onto the same port when a
+// GRPCHandler is set and no dedicated GRPCListenAddress is configured.
 func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if s.grpc != nil && s.grpcAddr == "" && isGRPCRequest(req) {
+		s.grpc.ServeHTTP(w, req)
+		return
+	}
 	s.router.ServeHTTP(w, req)
 }
 
 // Close this server
 func (s *Server) Close(ctx context.Context) (err error) {
+	if s.grpc != nil {
+		s.grpc.GracefulStop()
+	}
+	if s.acmeHTTP != nil {
+		s.acmeHTTP.Shutdown(ctx)
+	}
 	s.http.Shutdown(ctx)
 	return s.manager.Close()
 }
 
 // AddHandler adds a handler for the given method and path
 func (s *Server) AddHandler(method, path string, handler Handler) {
-	log.Info("adding handler").String("path", path).String("method", method).Log()
+	s.AddHandlerWithOptions(method, path, handler, Options{})
+}
 
-	if s.config.Username != "" && s.config.Password != "" {
-		handler = basicAuth(handler, s.config.Username, s.config.Password)
-	}
+// AddHandlerWithOptions adds a handler for the given method and path,
+// overriding the server wide defaults with opts.
+func (s *Server) AddHandlerWithOptions(method, path string, handler Handler, opts Options) {
+	log.Info("adding handler").String("path", path).String("method", method).Log()
 
-	s.router.Handle(method, path, logger(recovery(handler)))
+	s.router.Handle(method, path, s.chain(handler, opts))
 }
 
 // AddServerHandler adds a handler for the given method and path
 func (s *Server) AddServerHandler(method, path string, handler ServerHandler) {
 	log.Info("adding handler").String("path", path).String("method", method).Log()
 
-	var h Handler
-	switch s.config.Username != "" && s.config.Password != "" {
-	case true:
-		h = basicAuth(handler(s), s.config.Username, s.config.Password)
-	case false:
-		h = handler(s)
-	}
+	s.router.Handle(method, path, s.chain(handler(s), Options{}))
+}
 
-	s.router.Handle(method, path, logger(recovery(h)))
+// chain wraps handler with this server's middleware stack: logging,
+// in-flight limiting, per-route timeout, panic recovery and auth.
+//
+// recovery must sit inside withTimeout: http.TimeoutHandler runs the
+// wrapped handler in its own goroutine, so a recover() placed outside
+// it can never catch a panic raised inside.
+func (s *Server) chain(handler Handler, opts Options) (h Handler) {
+	h = auth(handler, func() Authenticator { return s.auth })
+	h = recovery(h)
+	h = withTimeout(h, opts.Timeout)
+	h = s.limit(h, opts.LongRunning)
+	return logger(h)
 }
 
 // ServerHandler is handler that has access to the server
@@ -179,18 +346,6 @@ func logger(h Handler) (n Handler) {
 	}
 }
 
-// basic auth middleware until we have proper auth
-func basicAuth(h Handler, user, password string) (n Handler) {
-	return func(w http.ResponseWriter, r *http.Request, ps Params) {
-		user, password, hasAuth := r.BasicAuth()
-		if hasAuth && user == user && password == password {
-			h(w, r, ps)
-		} else {
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
-		}
-	}
-}
-
 type statusWriter struct {
 	http.ResponseWriter
 	status int
@@ -0,0 +1,93 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"net/http"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSConfig configures serving the API over HTTPS.
+type TLSConfig struct {
+	Enabled  bool       `json:"enabled" yaml:"enabled"`
+	CertFile string     `json:"cert_file" yaml:"cert_file"`
+	KeyFile  string     `json:"key_file" yaml:"key_file"`
+	ACME     ACMEConfig `json:"acme" yaml:"acme"`
+}
+
+// ACMEConfig configures automatic certificate management through an ACME
+// provider such as Let's Encrypt.
+type ACMEConfig struct {
+	Enabled bool     `json:"enabled" yaml:"enabled"`
+	Email   string   `json:"email" yaml:"email"`
+	Domains []string `json:"domains" yaml:"domains"`
+	// CacheDir stores issued certificates across restarts.
+	CacheDir string `json:"cache_dir" yaml:"cache_dir"`
+	// DirectoryURL overrides the ACME directory endpoint, defaulting to
+	// Let's Encrypt's production directory.
+	DirectoryURL string `json:"directory_url" yaml:"directory_url"`
+	// HTTPListenAddress serves HTTP-01 challenges and redirects all
+	// other traffic to HTTPS, defaults to ":80".
+	HTTPListenAddress string `json:"http_listen_address" yaml:"http_listen_address"`
+}
+
+// configureTLS sets up s.http.TLSConfig according to config.TLS, and
+// builds the ACME HTTP-01 challenge server in s.acmeHTTP when autocert is
+// enabled; Start launches it.
+func (s *Server) configureTLS(config TLSConfig) (err error) {
+	if !config.Enabled {
+		return nil
+	}
+
+	if config.ACME.Enabled {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Email:      config.ACME.Email,
+			HostPolicy: autocert.HostWhitelist(config.ACME.Domains...),
+			Cache:      autocert.DirCache(config.ACME.CacheDir),
+		}
+
+		if config.ACME.DirectoryURL != "" {
+			manager.Client = &acme.Client{DirectoryURL: config.ACME.DirectoryURL}
+		}
+
+		s.http.TLSConfig = manager.TLSConfig()
+
+		httpAddr := config.ACME.HTTPListenAddress
+		if httpAddr == "" {
+			httpAddr = ":80"
+		}
+
+		s.acmeHTTP = &http.Server{
+			Addr:    httpAddr,
+			Handler: manager.HTTPHandler(nil),
+		}
+
+		return nil
+	}
+
+	if config.CertFile == "" || config.KeyFile == "" {
+		return fmt.Errorf("server: tls enabled but no cert_file/key_file or acme configured")
+	}
+
+	s.tlsCertFile = config.CertFile
+	s.tlsKeyFile = config.KeyFile
+	return nil
+}
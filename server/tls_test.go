@@ -0,0 +1,81 @@
+package server
+
+/*
+   Copyright 2019 Bruno Moura <brunotm@gmail.com>
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"net/http"
+	"testing"
+)
+
+func newTestTLSServer() *Server {
+	return &Server{http: &http.Server{}}
+}
+
+func TestConfigureTLSDisabled(t *testing.T) {
+	s := newTestTLSServer()
+
+	if err := s.configureTLS(TLSConfig{}); err != nil {
+		t.Fatalf("configureTLS: %v", err)
+	}
+	if s.http.TLSConfig != nil || s.tlsCertFile != "" || s.tlsKeyFile != "" {
+		t.Fatal("configureTLS mutated server state while TLS is disabled")
+	}
+}
+
+func TestConfigureTLSMissingCertOrKey(t *testing.T) {
+	s := newTestTLSServer()
+
+	err := s.configureTLS(TLSConfig{Enabled: true})
+	if err == nil {
+		t.Fatal("configureTLS succeeded with no cert_file/key_file or acme configured")
+	}
+}
+
+func TestConfigureTLSCertFile(t *testing.T) {
+	s := newTestTLSServer()
+
+	err := s.configureTLS(TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem"})
+	if err != nil {
+		t.Fatalf("configureTLS: %v", err)
+	}
+	if s.tlsCertFile != "cert.pem" || s.tlsKeyFile != "key.pem" {
+		t.Fatalf("tlsCertFile/tlsKeyFile = %q/%q, want cert.pem/key.pem", s.tlsCertFile, s.tlsKeyFile)
+	}
+}
+
+func TestConfigureTLSACME(t *testing.T) {
+	s := newTestTLSServer()
+
+	err := s.configureTLS(TLSConfig{
+		Enabled: true,
+		ACME: ACMEConfig{
+			Enabled:           true,
+			Domains:           []string{"example.com"},
+			CacheDir:          t.TempDir(),
+			HTTPListenAddress: ":8080",
+		},
+	})
+	if err != nil {
+		t.Fatalf("configureTLS: %v", err)
+	}
+	if s.http.TLSConfig == nil {
+		t.Fatal("configureTLS with ACME enabled did not set http.TLSConfig")
+	}
+	if s.acmeHTTP == nil || s.acmeHTTP.Addr != ":8080" {
+		t.Fatal("configureTLS with ACME enabled did not set up the HTTP-01 challenge server")
+	}
+}